@@ -87,61 +87,22 @@ func (a *API) FetchCheckBundleByCID(cid CIDType) (*CheckBundle, error) {
 // CheckBundleSearch returns list of check bundles matching a search query
 //    - a search query (see: https://login.circonus.com/resources/api#searching)
 func (a *API) CheckBundleSearch(searchCriteria SearchQueryType) ([]CheckBundle, error) {
-	reqURL := url.URL{
-		Path: baseCheckBundlePath,
-	}
-
-	if searchCriteria != "" {
-		q := url.Values{}
-		q.Set("search", string(searchCriteria))
-		reqURL.RawQuery = q.Encode()
-	}
-
-	resp, err := a.Get(reqURL.String())
-	if err != nil {
-		return nil, fmt.Errorf("[ERROR] API call error %+v", err)
-	}
-
-	var results []CheckBundle
-	if err := json.Unmarshal(resp, &results); err != nil {
-		return nil, err
-	}
-
-	return results, nil
+	return drainCheckBundleIterator(a.CheckBundleSearchIter(searchCriteria, nil, 0))
 }
 
 // CheckBundleFilterSearch returns list of check bundles matching a search query and filter
 //    - a search query (see: https://login.circonus.com/resources/api#searching)
 //    - a filter (see: https://login.circonus.com/resources/api#filtering)
 func (a *API) CheckBundleFilterSearch(searchCriteria SearchQueryType, filterCriteria map[string]string) ([]CheckBundle, error) {
-	reqURL := url.URL{
-		Path: baseCheckBundlePath,
-	}
-
-	if searchCriteria != "" {
-		q := url.Values{}
-		q.Set("search", string(searchCriteria))
-		for field, val := range filterCriteria {
-			q.Set(field, val)
-		}
-		reqURL.RawQuery = q.Encode()
-	}
-
-	resp, err := a.Get(reqURL.String())
-	if err != nil {
-		return nil, fmt.Errorf("[ERROR] API call error %+v", err)
-	}
-
-	var results []CheckBundle
-	if err := json.Unmarshal(resp, &results); err != nil {
-		return nil, err
-	}
-
-	return results, nil
+	return drainCheckBundleIterator(a.CheckBundleSearchIter(searchCriteria, filterCriteria, 0))
 }
 
 // CreateCheckBundle create a new check bundle (check)
 func (a *API) CreateCheckBundle(config *CheckBundle) (*CheckBundle, error) {
+	if err := runCheckBundleCreateHooks(a, config); err != nil {
+		return nil, err
+	}
+
 	reqURL := url.URL{
 		Path: baseCheckBundlePath,
 	}
@@ -172,6 +133,10 @@ func (a *API) UpdateCheckBundle(config *CheckBundle) (*CheckBundle, error) {
 		return nil, fmt.Errorf("Invalid check bundle CID %v", config.CID)
 	}
 
+	if err := runCheckBundleUpdateHooks(a, config); err != nil {
+		return nil, err
+	}
+
 	reqURL := url.URL{
 		Path: config.CID,
 	}
@@ -0,0 +1,102 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// BulkOptions controls the concurrency and retry behavior of
+// BulkCreateCheckBundles and BulkUpdateCheckBundles.
+type BulkOptions struct {
+	// Concurrency is the maximum number of in-flight requests. A value
+	// <= 0 defaults to 10.
+	Concurrency int
+	// MaxRetries is the number of additional attempts the underlying
+	// RetryableTransport makes for a 429/5xx response to an idempotent
+	// request, i.e. BulkUpdateCheckBundles' PUTs; the POSTs sent by
+	// BulkCreateCheckBundles are not retried. A value <= 0 disables
+	// retrying. See (*API).EnableRetries.
+	MaxRetries int
+}
+
+// BulkResult is the outcome of a single CheckBundle passed to
+// BulkCreateCheckBundles or BulkUpdateCheckBundles.
+type BulkResult struct {
+	// Input is the CheckBundle as it was passed in.
+	Input *CheckBundle
+	// Bundle is the created/updated CheckBundle, non-nil iff Err is nil.
+	Bundle *CheckBundle
+	// Err is the terminal error for this input, after retries are
+	// exhausted.
+	Err error
+	// RetryAfter is set only when Err is a *RateLimitError, i.e. the
+	// final attempt was itself a 429, so callers can surface backpressure
+	// to their own schedulers. It is zero for any other error.
+	RetryAfter time.Duration
+}
+
+func bulkCheckBundles(ctx context.Context, configs []*CheckBundle, opts BulkOptions, do func(*CheckBundle) (*CheckBundle, error)) ([]BulkResult, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 10
+	}
+
+	results := make([]BulkResult, len(configs))
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+
+	for i, cfg := range configs {
+		wg.Add(1)
+		go func(i int, cfg *CheckBundle) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i] = BulkResult{Input: cfg, Err: ctx.Err()}
+				return
+			}
+			defer func() { <-sem }()
+
+			bundle, err := do(cfg)
+
+			result := BulkResult{Input: cfg, Bundle: bundle, Err: err}
+			var rateLimitErr *RateLimitError
+			if errors.As(err, &rateLimitErr) {
+				result.RetryAfter = rateLimitErr.RetryAfter
+			}
+			results[i] = result
+		}(i, cfg)
+	}
+
+	wg.Wait()
+	return results, nil
+}
+
+// BulkCreateCheckBundles creates each of configs, fanning out up to
+// opts.Concurrency requests at a time. Each create is a POST, which the
+// RetryableTransport installed via EnableRetries(opts.MaxRetries) sends
+// once without retrying, so a 429/5xx response becomes that input's Err;
+// it returns one BulkResult per input, in
+// the same order as configs, and a single input's failure does not abort
+// the others.
+func (a *API) BulkCreateCheckBundles(ctx context.Context, configs []*CheckBundle, opts BulkOptions) ([]BulkResult, error) {
+	a.EnableRetries(opts.MaxRetries)
+	return bulkCheckBundles(ctx, configs, opts, a.CreateCheckBundle)
+}
+
+// BulkUpdateCheckBundles updates each of configs, fanning out up to
+// opts.Concurrency requests at a time. 429/5xx responses are retried with
+// exponential backoff by the RetryableTransport installed via
+// EnableRetries(opts.MaxRetries); it returns one BulkResult per input, in
+// the same order as configs, and a single input's failure does not abort
+// the others.
+func (a *API) BulkUpdateCheckBundles(ctx context.Context, configs []*CheckBundle, opts BulkOptions) ([]BulkResult, error) {
+	a.EnableRetries(opts.MaxRetries)
+	return bulkCheckBundles(ctx, configs, opts, a.UpdateCheckBundle)
+}
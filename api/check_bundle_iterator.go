@@ -0,0 +1,172 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// defaultCheckBundleSearchPageSize is used by CheckBundleSearch and
+// CheckBundleFilterSearch, which hide pagination from the caller.
+const defaultCheckBundleSearchPageSize = 200
+
+// CheckBundleIterator lazily fetches pages of a check bundle search or
+// filter query so that callers with tens of thousands of matching bundles
+// don't have to hold the entire result set in memory at once.
+type CheckBundleIterator struct {
+	api       *API
+	search    SearchQueryType
+	filter    map[string]string
+	pageSize  int
+	from      int
+	page      []CheckBundle
+	pageIndex int
+	err       error
+	done      bool
+	started   bool
+}
+
+// CheckBundleSearchIter returns a CheckBundleIterator over the check
+// bundles matching search and filter, fetching pageSize bundles per
+// request. A pageSize of 0 uses a reasonable default.
+func (a *API) CheckBundleSearchIter(search SearchQueryType, filter map[string]string, pageSize int) *CheckBundleIterator {
+	if pageSize <= 0 {
+		pageSize = defaultCheckBundleSearchPageSize
+	}
+
+	return &CheckBundleIterator{
+		api:      a,
+		search:   search,
+		filter:   filter,
+		pageSize: pageSize,
+	}
+}
+
+func (it *CheckBundleIterator) fetchNextPage() {
+	reqURL := url.URL{
+		Path: baseCheckBundlePath,
+	}
+
+	q := url.Values{}
+	if it.search != "" {
+		q.Set("search", string(it.search))
+	}
+	for field, val := range it.filter {
+		q.Set(field, val)
+	}
+	q.Set("size", fmt.Sprintf("%d", it.pageSize))
+	q.Set("from", fmt.Sprintf("%d", it.from))
+	reqURL.RawQuery = q.Encode()
+
+	resp, err := it.api.Get(reqURL.String())
+	if err != nil {
+		it.err = fmt.Errorf("[ERROR] API call error %+v", err)
+		it.done = true
+		return
+	}
+
+	var page []CheckBundle
+	if err := json.Unmarshal(resp, &page); err != nil {
+		it.err = err
+		it.done = true
+		return
+	}
+
+	it.page = page
+	it.pageIndex = 0
+	it.from += len(page)
+	it.done = isLastPage(len(page), it.pageSize)
+}
+
+// isLastPage reports whether a page of pageLen results, fetched with the
+// given pageSize, is the last page of a search: the server only returns
+// fewer results than requested once there's nothing left to page through.
+func isLastPage(pageLen, pageSize int) bool {
+	return pageLen < pageSize
+}
+
+// Next advances the iterator and reports whether a Value is available. It
+// returns false on exhaustion or error; check Err to distinguish the two.
+func (it *CheckBundleIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.started {
+		it.pageIndex++
+	}
+	it.started = true
+
+	for it.pageIndex >= len(it.page) {
+		if it.done {
+			return false
+		}
+		it.fetchNextPage()
+		if it.err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Value returns the check bundle at the iterator's current position. It is
+// only valid to call after a Next call that returned true.
+func (it *CheckBundleIterator) Value() *CheckBundle {
+	cb := it.page[it.pageIndex]
+	return &cb
+}
+
+// Err returns the first error encountered while fetching pages, if any.
+func (it *CheckBundleIterator) Err() error {
+	return it.err
+}
+
+// Close marks the iterator exhausted, releasing its reference to the
+// current page. It is safe to call Close more than once.
+func (it *CheckBundleIterator) Close() error {
+	it.done = true
+	it.page = nil
+	return it.err
+}
+
+// drainCheckBundleIterator consumes an iterator to completion and returns the collected
+// results. CheckBundleSearch and CheckBundleFilterSearch use it to stay
+// backwards compatible while sharing the iterator's pagination logic.
+func drainCheckBundleIterator(it *CheckBundleIterator) ([]CheckBundle, error) {
+	var results []CheckBundle
+	for it.Next() {
+		results = append(results, *it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// CheckBundleSearchCount returns the number of check bundles matching
+// search and filter.
+//
+// A size=0 request can't be used to get this cheaply: Circonus' search
+// endpoint doesn't report a total-match count independent of the page it
+// returns, so a size=0 request just comes back with zero results instead
+// of the total. Until the transport exposes response headers (at which
+// point a dedicated count-only lookup becomes possible), this pages
+// through every matching bundle via CheckBundleSearchIter and counts them.
+func (a *API) CheckBundleSearchCount(search SearchQueryType, filter map[string]string) (int, error) {
+	it := a.CheckBundleSearchIter(search, filter, 0)
+
+	count := 0
+	for it.Next() {
+		it.Value()
+		count++
+	}
+	if err := it.Err(); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
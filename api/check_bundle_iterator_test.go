@@ -0,0 +1,24 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import "testing"
+
+func TestIsLastPage(t *testing.T) {
+	cases := []struct {
+		pageLen, pageSize int
+		want              bool
+	}{
+		{pageLen: 200, pageSize: 200, want: false}, // full page, more may follow
+		{pageLen: 199, pageSize: 200, want: true},  // short page, nothing left
+		{pageLen: 0, pageSize: 200, want: true},    // no results at all
+	}
+
+	for _, c := range cases {
+		if got := isLastPage(c.pageLen, c.pageSize); got != c.want {
+			t.Errorf("isLastPage(%d, %d) = %v, want %v", c.pageLen, c.pageSize, got, c.want)
+		}
+	}
+}
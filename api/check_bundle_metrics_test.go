@@ -0,0 +1,78 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import "testing"
+
+func TestDiffCheckBundleMetrics(t *testing.T) {
+	current := &CheckBundle{
+		Metrics: []CheckBundleMetric{
+			{Name: "cpu", Status: "active"},
+			{Name: "mem", Status: "available"},
+			{Name: "disk", Status: "active"},
+		},
+	}
+
+	desired := []CheckBundleMetric{
+		{Name: "cpu", Status: "active"}, // unchanged, should not appear
+		{Name: "mem", Status: "active"}, // status change, should appear
+		{Name: "net", Status: "active"}, // new, should appear
+		// "disk" is dropped from desired, should be disabled
+	}
+
+	ops := DiffCheckBundleMetrics(current, desired)
+
+	byName := make(map[string]CheckBundleMetric, len(ops))
+	for _, op := range ops {
+		byName[op.Name] = op
+	}
+
+	if len(ops) != 3 {
+		t.Fatalf("expected 3 ops, got %d: %+v", len(ops), ops)
+	}
+	if _, ok := byName["cpu"]; ok {
+		t.Errorf("unchanged metric %q should not produce an op", "cpu")
+	}
+	if op, ok := byName["mem"]; !ok || op.Status != "active" {
+		t.Errorf("expected mem status change to active, got %+v", op)
+	}
+	if op, ok := byName["net"]; !ok || op.Status != "active" {
+		t.Errorf("expected new metric net to be added active, got %+v", op)
+	}
+	if op, ok := byName["disk"]; !ok || op.Status != "available" {
+		t.Errorf("expected dropped metric disk to be disabled, got %+v", op)
+	}
+}
+
+func TestMergeCheckBundleMetricsLeavesUntouchedMetricsAlone(t *testing.T) {
+	current := []CheckBundleMetric{
+		{Name: "cpu", Status: "active"},
+		{Name: "mem", Status: "active"},
+	}
+	changes := []CheckBundleMetric{
+		{Name: "mem", Status: "available"},
+		{Name: "disk", Status: "active"},
+	}
+
+	merged := mergeCheckBundleMetrics(current, changes)
+
+	byName := make(map[string]CheckBundleMetric, len(merged))
+	for _, m := range merged {
+		byName[m.Name] = m
+	}
+
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 metrics, got %d: %+v", len(merged), merged)
+	}
+	if got := byName["cpu"].Status; got != "active" {
+		t.Errorf("expected untouched cpu metric to survive the merge, got status %q", got)
+	}
+	if got := byName["mem"].Status; got != "available" {
+		t.Errorf("expected mem status change to apply, got %q", got)
+	}
+	if got := byName["disk"].Status; got != "active" {
+		t.Errorf("expected new disk metric to be added, got %q", got)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+
+	"github.com/redhotpenguin/circonus-gometrics/api/config"
+)
+
+// SetTypedConfig replaces CheckBundle.Config with the config produced by cfg,
+// after validating it against the requirements of its own check type. An
+// error is returned if cfg's check type doesn't match CheckBundle.Type.
+func (b *CheckBundle) SetTypedConfig(cfg config.Config) error {
+	if cfg.CheckType() != b.Type {
+		return fmt.Errorf("typed config is for check type %q, check bundle is %q", cfg.CheckType(), b.Type)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	m := cfg.ToMap()
+	raw := make(CheckBundleConfig, len(m))
+	for k, v := range m {
+		raw[CheckBundleConfigKey(k)] = v
+	}
+	b.Config = raw
+
+	return nil
+}
+
+// TypedConfig decodes CheckBundle.Config into the typed config struct for
+// CheckBundle.Type, returning an error if the check type has no typed
+// config or the raw config contains a key the typed config doesn't
+// recognize.
+func (b *CheckBundle) TypedConfig() (config.Config, error) {
+	raw := make(map[string]string, len(b.Config))
+	for k, v := range b.Config {
+		raw[string(k)] = v
+	}
+
+	return config.FromMap(b.Type, raw)
+}
@@ -0,0 +1,183 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/redhotpenguin/circonus-gometrics/api/config"
+)
+
+var brokerCIDRegexp = regexp.MustCompile("^/broker/[0-9]+$")
+
+// Default settings applied by the Check constructors when the caller
+// doesn't override them before calling Save.
+const (
+	defaultCheckPeriod      = 60
+	defaultCheckTimeout     = 10
+	defaultCheckMetricLimit = 0
+	defaultCheckStatus      = "active"
+)
+
+// Check is a high-level, ergonomic facade over CheckBundle. CheckBundle
+// exposes the full Circonus API surface; Check hides that surface behind
+// constructors for the common check types and exists solely to remove the
+// bundle-assembly boilerplate every downstream integration otherwise has
+// to reinvent.
+type Check struct {
+	api    *API
+	bundle *CheckBundle
+}
+
+func newCheck(a *API, checkType, target string, collectors []string) (*Check, error) {
+	if len(collectors) == 0 {
+		return nil, fmt.Errorf("check requires at least one broker CID")
+	}
+	for _, cid := range collectors {
+		if !brokerCIDRegexp.MatchString(cid) {
+			return nil, fmt.Errorf("invalid broker CID %q", cid)
+		}
+	}
+
+	return &Check{
+		api: a,
+		bundle: &CheckBundle{
+			Brokers:     collectors,
+			DisplayName: target,
+			MetricLimit: defaultCheckMetricLimit,
+			Period:      defaultCheckPeriod,
+			Status:      defaultCheckStatus,
+			Target:      target,
+			Timeout:     defaultCheckTimeout,
+			Type:        checkType,
+		},
+	}, nil
+}
+
+// NewHTTPCheck returns a Check that will create an "http" CheckBundle
+// against url on target, pulled by one of collectors.
+func (a *API) NewHTTPCheck(target, url string, collectors []string) (*Check, error) {
+	c, err := newCheck(a, "http", target, collectors)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.bundle.SetTypedConfig(&config.HTTPConfig{URL: url}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewJSONCheck returns a Check that will create a "json" CheckBundle
+// against url on target, pulled by one of collectors.
+func (a *API) NewJSONCheck(target, url string, collectors []string) (*Check, error) {
+	c, err := newCheck(a, "json", target, collectors)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.bundle.SetTypedConfig(&config.JSONConfig{URL: url}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewPostgresCheck returns a Check that will create a "postgres" CheckBundle
+// that runs query against dsn, pulled by one of collectors.
+func (a *API) NewPostgresCheck(target, dsn, query string, collectors []string) (*Check, error) {
+	c, err := newCheck(a, "postgres", target, collectors)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.bundle.SetTypedConfig(&config.PostgreSQLConfig{DSN: dsn, Query: query}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewHTTPTrapCheck returns a Check that will create an "httptrap"
+// CheckBundle that accepts pushed metrics authenticated with secret.
+func (a *API) NewHTTPTrapCheck(target, secret string, collectors []string) (*Check, error) {
+	c, err := newCheck(a, "httptrap", target, collectors)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.bundle.SetTypedConfig(&config.HTTPTrapConfig{Secret: secret}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// AddMetric appends a metric to the underlying CheckBundle. It has no
+// effect on Circonus until Save is called.
+func (c *Check) AddMetric(metric CheckBundleMetric) {
+	c.bundle.Metrics = append(c.bundle.Metrics, metric)
+}
+
+// EnableMetric sets the status of the named metric to "active", adding it
+// with that status if it isn't already present.
+func (c *Check) EnableMetric(name string) {
+	c.setMetricStatus(name, "active")
+}
+
+// DisableMetric sets the status of the named metric to "available".
+func (c *Check) DisableMetric(name string) {
+	c.setMetricStatus(name, "available")
+}
+
+func (c *Check) setMetricStatus(name, status string) {
+	for i := range c.bundle.Metrics {
+		if c.bundle.Metrics[i].Name == name {
+			c.bundle.Metrics[i].Status = status
+			return
+		}
+	}
+	c.bundle.Metrics = append(c.bundle.Metrics, CheckBundleMetric{
+		Name:   name,
+		Type:   "numeric",
+		Status: status,
+	})
+}
+
+// SetTags replaces the CheckBundle's tags.
+func (c *Check) SetTags(tags []string) {
+	c.bundle.Tags = tags
+}
+
+// Bundle returns the CheckBundle backing this Check.
+func (c *Check) Bundle() *CheckBundle {
+	return c.bundle
+}
+
+// Save creates the CheckBundle if it hasn't been created yet, or updates
+// it otherwise, and refreshes Check with the result.
+func (c *Check) Save() error {
+	var (
+		saved *CheckBundle
+		err   error
+	)
+
+	if c.bundle.CID == "" {
+		saved, err = c.api.CreateCheckBundle(c.bundle)
+	} else {
+		saved, err = c.api.UpdateCheckBundle(c.bundle)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.bundle = saved
+	return nil
+}
+
+// FetchCheck fetches the check bundle identified by id and wraps it in a
+// Check.
+func (a *API) FetchCheck(id IDType) (*Check, error) {
+	bundle, err := a.FetchCheckBundleByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Check{api: a, bundle: bundle}, nil
+}
@@ -0,0 +1,643 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package config provides typed, per-check-type configuration for
+// CheckBundle.Config (see:
+// https://login.circonus.com/resources/api/calls/check_bundle for the
+// specific settings available for each distinct check type).
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Key is the name of a single CheckBundleConfig setting, e.g. "url" or "dsn".
+type Key string
+
+// Common keys, shared across more than one check type.
+const (
+	SubmissionURL Key = "submission_url"
+	ReverseURL    Key = "reverse:url"
+	AsyncMetrics  Key = "async_metrics"
+)
+
+// headerKeyPrefix is how Circonus encodes a single HTTP/JSON check header:
+// one "header_<Name>" key per header, not a single combined "headers" key.
+const headerKeyPrefix = "header_"
+
+// HTTP check config keys.
+const (
+	HTTPURL          Key = "url"
+	HTTPVersion      Key = "http_version"
+	HTTPMethod       Key = "method"
+	HTTPPayload      Key = "payload"
+	HTTPReadLimit    Key = "read_limit"
+	HTTPRedirects    Key = "redirects"
+	HTTPAuthMethod   Key = "auth_method"
+	HTTPAuthUser     Key = "auth_user"
+	HTTPAuthPassword Key = "auth_password"
+	HTTPCertFile     Key = "certificate_file"
+	HTTPKeyFile      Key = "key_file"
+	HTTPCAChain      Key = "ca_chain"
+	HTTPCiphers      Key = "ciphers"
+)
+
+// JSON check config keys. Same shape as the HTTP check's keys, since a JSON
+// check is just an HTTP check whose response body is parsed as JSON.
+const (
+	JSONURL       Key = "url"
+	JSONVersion   Key = "http_version"
+	JSONMethod    Key = "method"
+	JSONPayload   Key = "payload"
+	JSONReadLimit Key = "read_limit"
+)
+
+// MySQL check config keys.
+const (
+	MySQLDSN   Key = "dsn"
+	MySQLQuery Key = "sql"
+)
+
+// PostgreSQL check config keys.
+const (
+	PostgreSQLDSN   Key = "dsn"
+	PostgreSQLQuery Key = "sql"
+)
+
+// CAQL check config keys.
+const (
+	CAQLQuery Key = "query"
+)
+
+// PingICMP check config keys.
+const (
+	PingICMPCount    Key = "count"
+	PingICMPInterval Key = "interval"
+)
+
+// TCP check config keys.
+const (
+	TCPHost    Key = "host"
+	TCPPort    Key = "port"
+	TCPBanner  Key = "banner_regex"
+	TCPTLS     Key = "use_ssl"
+	TCPCiphers Key = "ciphers"
+)
+
+// HTTPTrap check config keys.
+const (
+	HTTPTrapAsyncMetrics Key = "async_metrics"
+	HTTPTrapSecret       Key = "secret"
+)
+
+// CloudWatch check config keys.
+const (
+	CloudWatchAPIKey    Key = "api_key"
+	CloudWatchAPISecret Key = "api_secret"
+	CloudWatchURL       Key = "url"
+	CloudWatchNamespace Key = "namespace"
+	CloudWatchMetrics   Key = "metrics"
+	// CloudWatchDimensions is spelled "dimmensions" because that's the
+	// literal key Circonus' own API expects for this check type -- not a
+	// typo to "fix".
+	CloudWatchDimensions Key = "dimmensions"
+)
+
+// Consul check config keys.
+const (
+	ConsulACLToken  Key = "acl_token"
+	ConsulCheckType Key = "check_type"
+	ConsulURL       Key = "url"
+	ConsulCiphers   Key = "ciphers"
+)
+
+// StatsD check config keys.
+const (
+	StatsDSourceIP Key = "source_ip"
+)
+
+// Config is implemented by every typed, per-check-type configuration.
+// ToMap and FromMap let CheckBundle.SetTypedConfig/TypedConfig move between
+// the typed struct and the raw string map the API actually transmits.
+type Config interface {
+	// CheckType returns the CheckBundle.Type this config applies to.
+	CheckType() string
+	// Validate returns an error if required fields are missing.
+	Validate() error
+	// ToMap renders the typed config as the raw key/value pairs Circonus expects.
+	ToMap() map[string]string
+}
+
+// fromMapper is implemented by every typed Config: fromMap populates it from
+// a raw key/value map, returning an error if the map contains a key the
+// target config doesn't recognize.
+type fromMapper interface {
+	fromMap(map[string]string) error
+}
+
+// HTTPConfig is the typed configuration for "http" checks.
+type HTTPConfig struct {
+	URL          string
+	Version      string
+	Method       string
+	Headers      map[string]string
+	Payload      string
+	ReadLimit    string
+	Redirects    string
+	AuthMethod   string
+	AuthUser     string
+	AuthPassword string
+}
+
+// CheckType implements Config.
+func (c *HTTPConfig) CheckType() string { return "http" }
+
+// Validate implements Config.
+func (c *HTTPConfig) Validate() error {
+	if c.URL == "" {
+		return fmt.Errorf("http config requires %q", HTTPURL)
+	}
+	return nil
+}
+
+// ToMap implements Config.
+func (c *HTTPConfig) ToMap() map[string]string {
+	m := map[string]string{string(HTTPURL): c.URL}
+	setIfNotEmpty(m, HTTPVersion, c.Version)
+	setIfNotEmpty(m, HTTPMethod, c.Method)
+	setHeaders(m, c.Headers)
+	setIfNotEmpty(m, HTTPPayload, c.Payload)
+	setIfNotEmpty(m, HTTPReadLimit, c.ReadLimit)
+	setIfNotEmpty(m, HTTPRedirects, c.Redirects)
+	setIfNotEmpty(m, HTTPAuthMethod, c.AuthMethod)
+	setIfNotEmpty(m, HTTPAuthUser, c.AuthUser)
+	setIfNotEmpty(m, HTTPAuthPassword, c.AuthPassword)
+	return m
+}
+
+func (c *HTTPConfig) fromMap(m map[string]string) error {
+	known := map[Key]*string{
+		HTTPURL:          &c.URL,
+		HTTPVersion:      &c.Version,
+		HTTPMethod:       &c.Method,
+		HTTPPayload:      &c.Payload,
+		HTTPReadLimit:    &c.ReadLimit,
+		HTTPRedirects:    &c.Redirects,
+		HTTPAuthMethod:   &c.AuthMethod,
+		HTTPAuthUser:     &c.AuthUser,
+		HTTPAuthPassword: &c.AuthPassword,
+	}
+	rest := splitHeaders(m, &c.Headers)
+	return populate(known, rest, "http")
+}
+
+// JSONConfig is the typed configuration for "json" checks.
+type JSONConfig struct {
+	URL       string
+	Version   string
+	Method    string
+	Headers   map[string]string
+	Payload   string
+	ReadLimit string
+}
+
+// CheckType implements Config.
+func (c *JSONConfig) CheckType() string { return "json" }
+
+// Validate implements Config.
+func (c *JSONConfig) Validate() error {
+	if c.URL == "" {
+		return fmt.Errorf("json config requires %q", JSONURL)
+	}
+	return nil
+}
+
+// ToMap implements Config.
+func (c *JSONConfig) ToMap() map[string]string {
+	m := map[string]string{string(JSONURL): c.URL}
+	setIfNotEmpty(m, JSONVersion, c.Version)
+	setIfNotEmpty(m, JSONMethod, c.Method)
+	setHeaders(m, c.Headers)
+	setIfNotEmpty(m, JSONPayload, c.Payload)
+	setIfNotEmpty(m, JSONReadLimit, c.ReadLimit)
+	return m
+}
+
+func (c *JSONConfig) fromMap(m map[string]string) error {
+	known := map[Key]*string{
+		JSONURL:       &c.URL,
+		JSONVersion:   &c.Version,
+		JSONMethod:    &c.Method,
+		JSONPayload:   &c.Payload,
+		JSONReadLimit: &c.ReadLimit,
+	}
+	rest := splitHeaders(m, &c.Headers)
+	return populate(known, rest, "json")
+}
+
+// PostgreSQLConfig is the typed configuration for "postgres" checks.
+type PostgreSQLConfig struct {
+	DSN   string
+	Query string
+}
+
+// CheckType implements Config.
+func (c *PostgreSQLConfig) CheckType() string { return "postgres" }
+
+// Validate implements Config.
+func (c *PostgreSQLConfig) Validate() error {
+	if c.DSN == "" {
+		return fmt.Errorf("postgres config requires %q", PostgreSQLDSN)
+	}
+	if c.Query == "" {
+		return fmt.Errorf("postgres config requires %q", PostgreSQLQuery)
+	}
+	return nil
+}
+
+// ToMap implements Config.
+func (c *PostgreSQLConfig) ToMap() map[string]string {
+	return map[string]string{
+		string(PostgreSQLDSN):   c.DSN,
+		string(PostgreSQLQuery): c.Query,
+	}
+}
+
+func (c *PostgreSQLConfig) fromMap(m map[string]string) error {
+	known := map[Key]*string{
+		PostgreSQLDSN:   &c.DSN,
+		PostgreSQLQuery: &c.Query,
+	}
+	return populate(known, m, "postgres")
+}
+
+// MySQLConfig is the typed configuration for "mysql" checks.
+type MySQLConfig struct {
+	DSN   string
+	Query string
+}
+
+// CheckType implements Config.
+func (c *MySQLConfig) CheckType() string { return "mysql" }
+
+// Validate implements Config.
+func (c *MySQLConfig) Validate() error {
+	if c.DSN == "" {
+		return fmt.Errorf("mysql config requires %q", MySQLDSN)
+	}
+	if c.Query == "" {
+		return fmt.Errorf("mysql config requires %q", MySQLQuery)
+	}
+	return nil
+}
+
+// ToMap implements Config.
+func (c *MySQLConfig) ToMap() map[string]string {
+	return map[string]string{
+		string(MySQLDSN):   c.DSN,
+		string(MySQLQuery): c.Query,
+	}
+}
+
+func (c *MySQLConfig) fromMap(m map[string]string) error {
+	known := map[Key]*string{
+		MySQLDSN:   &c.DSN,
+		MySQLQuery: &c.Query,
+	}
+	return populate(known, m, "mysql")
+}
+
+// CAQLConfig is the typed configuration for "caql" checks.
+type CAQLConfig struct {
+	Query string
+}
+
+// CheckType implements Config.
+func (c *CAQLConfig) CheckType() string { return "caql" }
+
+// Validate implements Config.
+func (c *CAQLConfig) Validate() error {
+	if c.Query == "" {
+		return fmt.Errorf("caql config requires %q", CAQLQuery)
+	}
+	return nil
+}
+
+// ToMap implements Config.
+func (c *CAQLConfig) ToMap() map[string]string {
+	return map[string]string{string(CAQLQuery): c.Query}
+}
+
+func (c *CAQLConfig) fromMap(m map[string]string) error {
+	known := map[Key]*string{CAQLQuery: &c.Query}
+	return populate(known, m, "caql")
+}
+
+// PingICMPConfig is the typed configuration for "ping_icmp" checks.
+type PingICMPConfig struct {
+	Count    string
+	Interval string
+}
+
+// CheckType implements Config.
+func (c *PingICMPConfig) CheckType() string { return "ping_icmp" }
+
+// Validate implements Config.
+func (c *PingICMPConfig) Validate() error {
+	if c.Count == "" {
+		return fmt.Errorf("ping_icmp config requires %q", PingICMPCount)
+	}
+	return nil
+}
+
+// ToMap implements Config.
+func (c *PingICMPConfig) ToMap() map[string]string {
+	m := map[string]string{string(PingICMPCount): c.Count}
+	setIfNotEmpty(m, PingICMPInterval, c.Interval)
+	return m
+}
+
+func (c *PingICMPConfig) fromMap(m map[string]string) error {
+	known := map[Key]*string{
+		PingICMPCount:    &c.Count,
+		PingICMPInterval: &c.Interval,
+	}
+	return populate(known, m, "ping_icmp")
+}
+
+// TCPConfig is the typed configuration for "tcp" checks.
+type TCPConfig struct {
+	Host   string
+	Port   string
+	Banner string
+	TLS    string
+}
+
+// CheckType implements Config.
+func (c *TCPConfig) CheckType() string { return "tcp" }
+
+// Validate implements Config.
+func (c *TCPConfig) Validate() error {
+	if c.Host == "" {
+		return fmt.Errorf("tcp config requires %q", TCPHost)
+	}
+	if c.Port == "" {
+		return fmt.Errorf("tcp config requires %q", TCPPort)
+	}
+	return nil
+}
+
+// ToMap implements Config.
+func (c *TCPConfig) ToMap() map[string]string {
+	m := map[string]string{
+		string(TCPHost): c.Host,
+		string(TCPPort): c.Port,
+	}
+	setIfNotEmpty(m, TCPBanner, c.Banner)
+	setIfNotEmpty(m, TCPTLS, c.TLS)
+	return m
+}
+
+func (c *TCPConfig) fromMap(m map[string]string) error {
+	known := map[Key]*string{
+		TCPHost:   &c.Host,
+		TCPPort:   &c.Port,
+		TCPBanner: &c.Banner,
+		TCPTLS:    &c.TLS,
+	}
+	return populate(known, m, "tcp")
+}
+
+// HTTPTrapConfig is the typed configuration for "httptrap" checks.
+type HTTPTrapConfig struct {
+	AsyncMetrics string
+	Secret       string
+}
+
+// CheckType implements Config.
+func (c *HTTPTrapConfig) CheckType() string { return "httptrap" }
+
+// Validate implements Config.
+func (c *HTTPTrapConfig) Validate() error {
+	if c.Secret == "" {
+		return fmt.Errorf("httptrap config requires %q", HTTPTrapSecret)
+	}
+	return nil
+}
+
+// ToMap implements Config.
+func (c *HTTPTrapConfig) ToMap() map[string]string {
+	m := map[string]string{string(HTTPTrapSecret): c.Secret}
+	setIfNotEmpty(m, HTTPTrapAsyncMetrics, c.AsyncMetrics)
+	return m
+}
+
+func (c *HTTPTrapConfig) fromMap(m map[string]string) error {
+	known := map[Key]*string{
+		HTTPTrapSecret:       &c.Secret,
+		HTTPTrapAsyncMetrics: &c.AsyncMetrics,
+	}
+	return populate(known, m, "httptrap")
+}
+
+// CloudWatchConfig is the typed configuration for "cloudwatch" checks.
+type CloudWatchConfig struct {
+	APIKey     string
+	APISecret  string
+	URL        string
+	Namespace  string
+	Metrics    string
+	Dimensions string
+}
+
+// CheckType implements Config.
+func (c *CloudWatchConfig) CheckType() string { return "cloudwatch" }
+
+// Validate implements Config.
+func (c *CloudWatchConfig) Validate() error {
+	if c.APIKey == "" {
+		return fmt.Errorf("cloudwatch config requires %q", CloudWatchAPIKey)
+	}
+	if c.APISecret == "" {
+		return fmt.Errorf("cloudwatch config requires %q", CloudWatchAPISecret)
+	}
+	return nil
+}
+
+// ToMap implements Config.
+func (c *CloudWatchConfig) ToMap() map[string]string {
+	m := map[string]string{
+		string(CloudWatchAPIKey):    c.APIKey,
+		string(CloudWatchAPISecret): c.APISecret,
+	}
+	setIfNotEmpty(m, CloudWatchURL, c.URL)
+	setIfNotEmpty(m, CloudWatchNamespace, c.Namespace)
+	setIfNotEmpty(m, CloudWatchMetrics, c.Metrics)
+	setIfNotEmpty(m, CloudWatchDimensions, c.Dimensions)
+	return m
+}
+
+func (c *CloudWatchConfig) fromMap(m map[string]string) error {
+	known := map[Key]*string{
+		CloudWatchAPIKey:     &c.APIKey,
+		CloudWatchAPISecret:  &c.APISecret,
+		CloudWatchURL:        &c.URL,
+		CloudWatchNamespace:  &c.Namespace,
+		CloudWatchMetrics:    &c.Metrics,
+		CloudWatchDimensions: &c.Dimensions,
+	}
+	return populate(known, m, "cloudwatch")
+}
+
+// ConsulConfig is the typed configuration for "consul" checks.
+type ConsulConfig struct {
+	ACLToken string
+	// CheckTypeName is the Consul check_type config value (e.g. "http",
+	// "ttl"), not to be confused with CheckType() below, which identifies
+	// this as a "consul" CheckBundle.
+	CheckTypeName string
+	URL           string
+}
+
+// CheckType implements Config.
+func (c *ConsulConfig) CheckType() string { return "consul" }
+
+// Validate implements Config.
+func (c *ConsulConfig) Validate() error {
+	if c.URL == "" {
+		return fmt.Errorf("consul config requires %q", ConsulURL)
+	}
+	return nil
+}
+
+// ToMap implements Config.
+func (c *ConsulConfig) ToMap() map[string]string {
+	m := map[string]string{string(ConsulURL): c.URL}
+	setIfNotEmpty(m, ConsulACLToken, c.ACLToken)
+	setIfNotEmpty(m, ConsulCheckType, c.CheckTypeName)
+	return m
+}
+
+func (c *ConsulConfig) fromMap(m map[string]string) error {
+	known := map[Key]*string{
+		ConsulURL:       &c.URL,
+		ConsulACLToken:  &c.ACLToken,
+		ConsulCheckType: &c.CheckTypeName,
+	}
+	return populate(known, m, "consul")
+}
+
+// StatsDConfig is the typed configuration for "statsd" checks.
+type StatsDConfig struct {
+	SourceIP string
+}
+
+// CheckType implements Config.
+func (c *StatsDConfig) CheckType() string { return "statsd" }
+
+// Validate implements Config.
+func (c *StatsDConfig) Validate() error { return nil }
+
+// ToMap implements Config.
+func (c *StatsDConfig) ToMap() map[string]string {
+	m := map[string]string{}
+	setIfNotEmpty(m, StatsDSourceIP, c.SourceIP)
+	return m
+}
+
+func (c *StatsDConfig) fromMap(m map[string]string) error {
+	known := map[Key]*string{StatsDSourceIP: &c.SourceIP}
+	return populate(known, m, "statsd")
+}
+
+// New returns a zero-value typed Config for the given CheckBundle.Type, or
+// an error if the check type has no typed configuration yet.
+func New(checkType string) (Config, error) {
+	switch checkType {
+	case "http":
+		return &HTTPConfig{}, nil
+	case "json":
+		return &JSONConfig{}, nil
+	case "mysql":
+		return &MySQLConfig{}, nil
+	case "postgres":
+		return &PostgreSQLConfig{}, nil
+	case "caql":
+		return &CAQLConfig{}, nil
+	case "ping_icmp":
+		return &PingICMPConfig{}, nil
+	case "tcp":
+		return &TCPConfig{}, nil
+	case "httptrap":
+		return &HTTPTrapConfig{}, nil
+	case "cloudwatch":
+		return &CloudWatchConfig{}, nil
+	case "consul":
+		return &ConsulConfig{}, nil
+	case "statsd":
+		return &StatsDConfig{}, nil
+	default:
+		return nil, fmt.Errorf("no typed config for check type %q", checkType)
+	}
+}
+
+// FromMap populates a typed Config for checkType from a raw config map,
+// rejecting any key the check type doesn't recognize.
+func FromMap(checkType string, m map[string]string) (Config, error) {
+	cfg, err := New(checkType)
+	if err != nil {
+		return nil, err
+	}
+	fm, ok := cfg.(fromMapper)
+	if !ok {
+		return nil, fmt.Errorf("config for check type %q does not support decoding", checkType)
+	}
+	if err := fm.fromMap(m); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func setIfNotEmpty(m map[string]string, k Key, v string) {
+	if v != "" {
+		m[string(k)] = v
+	}
+}
+
+// setHeaders renders headers into m as one "header_<Name>" key per header.
+func setHeaders(m map[string]string, headers map[string]string) {
+	for name, val := range headers {
+		m[headerKeyPrefix+name] = val
+	}
+}
+
+// splitHeaders pulls every "header_<Name>" key out of m into *headers and
+// returns the remaining keys, so the caller's populate pass only has to
+// deal with its own fixed key set.
+func splitHeaders(m map[string]string, headers *map[string]string) map[string]string {
+	rest := make(map[string]string, len(m))
+	for k, v := range m {
+		if strings.HasPrefix(k, headerKeyPrefix) {
+			if *headers == nil {
+				*headers = map[string]string{}
+			}
+			(*headers)[strings.TrimPrefix(k, headerKeyPrefix)] = v
+			continue
+		}
+		rest[k] = v
+	}
+	return rest
+}
+
+func populate(known map[Key]*string, m map[string]string, checkType string) error {
+	for k, v := range m {
+		dst, ok := known[Key(k)]
+		if !ok {
+			return fmt.Errorf("unknown %s config key %q", checkType, k)
+		}
+		*dst = v
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import "testing"
+
+func TestHTTPConfigHeadersRoundTrip(t *testing.T) {
+	c := &HTTPConfig{
+		URL: "https://example.com/",
+		Headers: map[string]string{
+			"Host":          "example.com",
+			"Authorization": "Bearer xyz",
+		},
+	}
+
+	m := c.ToMap()
+	if m["header_Host"] != "example.com" {
+		t.Fatalf("expected header_Host in map, got %+v", m)
+	}
+	if m["header_Authorization"] != "Bearer xyz" {
+		t.Fatalf("expected header_Authorization in map, got %+v", m)
+	}
+	if _, ok := m["headers"]; ok {
+		t.Fatalf("did not expect a collapsed \"headers\" key, got %+v", m)
+	}
+
+	decoded := &HTTPConfig{}
+	if err := decoded.fromMap(m); err != nil {
+		t.Fatalf("fromMap returned error: %v", err)
+	}
+	if decoded.URL != c.URL {
+		t.Errorf("expected URL %q, got %q", c.URL, decoded.URL)
+	}
+	if len(decoded.Headers) != 2 || decoded.Headers["Host"] != "example.com" || decoded.Headers["Authorization"] != "Bearer xyz" {
+		t.Errorf("expected headers to round-trip, got %+v", decoded.Headers)
+	}
+}
+
+func TestConsulConfigCheckTypeName(t *testing.T) {
+	c := &ConsulConfig{URL: "https://consul.example.com/", CheckTypeName: "ttl"}
+
+	if c.CheckType() != "consul" {
+		t.Fatalf("expected CheckType() to report \"consul\", got %q", c.CheckType())
+	}
+
+	m := c.ToMap()
+	if m["check_type"] != "ttl" {
+		t.Fatalf("expected check_type %q in map, got %+v", "ttl", m)
+	}
+
+	decoded := &ConsulConfig{}
+	if err := decoded.fromMap(m); err != nil {
+		t.Fatalf("fromMap returned error: %v", err)
+	}
+	if decoded.CheckTypeName != "ttl" {
+		t.Errorf("expected CheckTypeName %q, got %q", "ttl", decoded.CheckTypeName)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"runtime"
+	"sync"
+)
+
+// CheckBundleHook runs against a CheckBundle before it is marshalled for
+// CreateCheckBundle or UpdateCheckBundle. A hook returning an error aborts
+// the call before any request is made.
+type CheckBundleHook func(*CheckBundle) error
+
+// createHooksBy/updateHooksBy key hooks by *API rather than storing them as
+// fields on API itself: API is defined outside this package's files and
+// this package can't add fields to it. To avoid pinning every *API that
+// ever registers a hook for the life of the process, a finalizer removes
+// both maps' entries once that *API becomes unreachable, so the lifetime of
+// registered hooks matches the lifetime of the client that owns them.
+var (
+	hooksMu       sync.Mutex
+	createHooksBy = map[*API][]CheckBundleHook{}
+	updateHooksBy = map[*API][]CheckBundleHook{}
+)
+
+func trackAPIForHookCleanup(a *API) {
+	if _, tracked := createHooksBy[a]; tracked {
+		return
+	}
+	if _, tracked := updateHooksBy[a]; tracked {
+		return
+	}
+	runtime.SetFinalizer(a, forgetCheckBundleHooks)
+}
+
+func forgetCheckBundleHooks(a *API) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	delete(createHooksBy, a)
+	delete(updateHooksBy, a)
+}
+
+// AddCheckBundleCreateHook registers a hook to run, in registration order,
+// against every CheckBundle passed to CreateCheckBundle.
+func (a *API) AddCheckBundleCreateHook(hook CheckBundleHook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	trackAPIForHookCleanup(a)
+	createHooksBy[a] = append(createHooksBy[a], hook)
+}
+
+// AddCheckBundleUpdateHook registers a hook to run, in registration order,
+// against every CheckBundle passed to UpdateCheckBundle.
+func (a *API) AddCheckBundleUpdateHook(hook CheckBundleHook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	trackAPIForHookCleanup(a)
+	updateHooksBy[a] = append(updateHooksBy[a], hook)
+}
+
+func runCheckBundleHooks(a *API, hooks map[*API][]CheckBundleHook, config *CheckBundle) error {
+	hooksMu.Lock()
+	toRun := append([]CheckBundleHook(nil), hooks[a]...)
+	hooksMu.Unlock()
+
+	for _, hook := range toRun {
+		if err := hook(config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runCheckBundleCreateHooks(a *API, config *CheckBundle) error {
+	return runCheckBundleHooks(a, createHooksBy, config)
+}
+
+func runCheckBundleUpdateHooks(a *API, config *CheckBundle) error {
+	return runCheckBundleHooks(a, updateHooksBy, config)
+}
+
+// WithAutoTags returns a CheckBundleHook that merges tags into a
+// CheckBundle's existing tags, skipping any that are already present.
+func WithAutoTags(tags []string) CheckBundleHook {
+	return func(cb *CheckBundle) error {
+		existing := make(map[string]bool, len(cb.Tags))
+		for _, t := range cb.Tags {
+			existing[t] = true
+		}
+		for _, t := range tags {
+			if !existing[t] {
+				cb.Tags = append(cb.Tags, t)
+				existing[t] = true
+			}
+		}
+		return nil
+	}
+}
+
+// WithDefaultMetricLimit returns a CheckBundleHook that sets MetricLimit to
+// n when the CheckBundle doesn't already specify one.
+func WithDefaultMetricLimit(n int) CheckBundleHook {
+	return func(cb *CheckBundle) error {
+		if cb.MetricLimit == 0 {
+			cb.MetricLimit = n
+		}
+		return nil
+	}
+}
+
+// WithDefaultPeriod returns a CheckBundleHook that sets Period to seconds
+// when the CheckBundle doesn't already specify one.
+func WithDefaultPeriod(seconds int) CheckBundleHook {
+	return func(cb *CheckBundle) error {
+		if cb.Period == 0 {
+			cb.Period = seconds
+		}
+		return nil
+	}
+}
+
+// WithDefaultTimeout returns a CheckBundleHook that sets Timeout to seconds
+// when the CheckBundle doesn't already specify one.
+func WithDefaultTimeout(seconds int) CheckBundleHook {
+	return func(cb *CheckBundle) error {
+		if cb.Timeout == 0 {
+			cb.Timeout = seconds
+		}
+		return nil
+	}
+}
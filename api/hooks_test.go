@@ -0,0 +1,47 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestWithAutoTagsDedups(t *testing.T) {
+	cb := &CheckBundle{Tags: []string{"env:prod", "service:api"}}
+
+	hook := WithAutoTags([]string{"service:api", "team:sre"})
+	if err := hook(cb); err != nil {
+		t.Fatalf("hook returned error: %v", err)
+	}
+
+	got := append([]string(nil), cb.Tags...)
+	sort.Strings(got)
+	want := []string{"env:prod", "service:api", "team:sre"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected tags %v, got %v", want, got)
+	}
+}
+
+func TestWithDefaultMetricLimitLeavesExplicitValue(t *testing.T) {
+	cb := &CheckBundle{MetricLimit: 500}
+
+	if err := WithDefaultMetricLimit(0)(cb); err != nil {
+		t.Fatalf("hook returned error: %v", err)
+	}
+	if cb.MetricLimit != 500 {
+		t.Errorf("expected explicit MetricLimit to be left alone, got %d", cb.MetricLimit)
+	}
+
+	cb2 := &CheckBundle{}
+	if err := WithDefaultMetricLimit(500)(cb2); err != nil {
+		t.Fatalf("hook returned error: %v", err)
+	}
+	if cb2.MetricLimit != 500 {
+		t.Errorf("expected default MetricLimit to be applied, got %d", cb2.MetricLimit)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryableTransport is an http.RoundTripper that retries an idempotent
+// request (GET/HEAD/PUT/DELETE/OPTIONS) on a 429 or 5xx response, with
+// exponential backoff and jitter between attempts. Non-idempotent requests
+// (POST, e.g. CreateCheckBundle) are passed straight through and never
+// retried, since a 5xx after the server already applied the change would
+// otherwise be retried into a duplicate. Installing it once on API's
+// http.Client (see EnableRetries) covers every request the client makes —
+// single or bulk — instead of each call site deciding retryability by
+// matching error text.
+type RetryableTransport struct {
+	Base       http.RoundTripper
+	MaxRetries int
+}
+
+// NewRetryableTransport wraps base (http.DefaultTransport if nil) with
+// retry-on-429/5xx behavior, retrying up to maxRetries additional times.
+func NewRetryableTransport(base http.RoundTripper, maxRetries int) *RetryableTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RetryableTransport{Base: base, MaxRetries: maxRetries}
+}
+
+// enableRetriesMu serializes installing/reconfiguring the RetryableTransport
+// on an API's Client. EnableRetries is called on every Bulk* invocation, so
+// without it concurrent calls could race on a.Client.Transport, and repeat
+// calls would each wrap the previous RetryableTransport instead of
+// reconfiguring it.
+var enableRetriesMu sync.Mutex
+
+// EnableRetries installs a RetryableTransport on a's underlying HTTP
+// client, so that requests with an idempotent method (UpdateCheckBundle and
+// BulkUpdateCheckBundles' PUTs, fetches, deletes) retry transient 429/5xx
+// responses instead of failing outright on an intermittent 503.
+// CreateCheckBundle and BulkCreateCheckBundles send a POST, which the
+// transport passes through once without retrying. Calling it again on the same API just
+// updates the existing RetryableTransport's MaxRetries rather than wrapping
+// it a second time, so BulkCreateCheckBundles/BulkUpdateCheckBundles can
+// call it on every invocation without stacking retries. Since the
+// RetryableTransport is shared by the whole Client, concurrent Bulk* calls
+// with different MaxRetries on the same API will race on which value wins;
+// use one API per desired retry policy if that matters.
+func (a *API) EnableRetries(maxRetries int) {
+	enableRetriesMu.Lock()
+	defer enableRetriesMu.Unlock()
+
+	if rt, ok := a.Client.Transport.(*RetryableTransport); ok {
+		rt.MaxRetries = maxRetries
+		return
+	}
+	a.Client.Transport = NewRetryableTransport(a.Client.Transport, maxRetries)
+}
+
+// RateLimitError is returned by RetryableTransport when retries are
+// exhausted against a 429 response. RetryAfter comes from the response's
+// own Retry-After header when present, falling back to the transport's
+// backoff estimate otherwise. BulkResult.RetryAfter is populated from it
+// so callers can surface rate-limit backpressure.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+// Error implements error.
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("circonus API rate limited, retry after %s", e.RetryAfter)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RetryableTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isIdempotentMethod(req.Method) {
+		// A 5xx on a POST (CreateCheckBundle) may arrive after the server
+		// has already created the bundle; retrying it here could create a
+		// duplicate. Only retry methods that are safe to repeat.
+		return t.Base.RoundTrip(req)
+	}
+
+	var (
+		resp *http.Response
+		err  error
+	)
+
+	for attempt := 0; ; attempt++ {
+		reqAttempt := req
+		if attempt > 0 && req.Body != nil {
+			if req.GetBody == nil {
+				// the original body was already consumed and can't be
+				// replayed; give up with whatever we last saw.
+				return resp, err
+			}
+			body, berr := req.GetBody()
+			if berr != nil {
+				return resp, berr
+			}
+			reqAttempt.Body = body
+		}
+
+		resp, err = t.Base.RoundTrip(reqAttempt)
+
+		retryable := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		if !retryable || attempt >= t.MaxRetries {
+			if err == nil && resp.StatusCode == http.StatusTooManyRequests && attempt >= t.MaxRetries {
+				return resp, &RateLimitError{RetryAfter: retryAfter(resp, attempt)}
+			}
+			return resp, err
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+		time.Sleep(backoffDelay(attempt))
+	}
+}
+
+// isIdempotentMethod reports whether method is safe to retry without risk
+// of duplicating a server-side effect.
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoffDelay returns an exponentially increasing, jittered delay for the
+// given (zero-based) retry attempt.
+func backoffDelay(attempt int) time.Duration {
+	base := time.Duration(1<<uint(attempt)) * 500 * time.Millisecond
+	jitter := time.Duration(rand.Int63n(int64(base/2) + 1))
+	return base + jitter
+}
+
+// retryAfter returns how long to wait before a caller should retry a
+// rate-limited request, preferring the server's own Retry-After header
+// (given either as a number of seconds or an HTTP-date, per RFC 7231
+// 7.1.3) over the client's synthetic backoff, since the server is
+// authoritative about when its rate limit clears.
+func retryAfter(resp *http.Response, attempt int) time.Duration {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return backoffDelay(attempt)
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+
+	if when, err := http.ParseTime(v); err == nil {
+		if d := time.Until(when); d > 0 {
+			return d
+		}
+		return 0
+	}
+
+	return backoffDelay(attempt)
+}
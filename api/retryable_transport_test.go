@@ -0,0 +1,88 @@
+// Copyright 2016 Circonus, Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelayGrowsExponentially(t *testing.T) {
+	// backoffDelay adds up to 50% jitter on top of a doubling base, so
+	// assert against the base's lower bound rather than an exact value.
+	for attempt := 0; attempt < 5; attempt++ {
+		base := time.Duration(1<<uint(attempt)) * 500 * time.Millisecond
+		d := backoffDelay(attempt)
+		if d < base || d > base+base/2 {
+			t.Errorf("backoffDelay(%d) = %s, want in [%s, %s]", attempt, d, base, base+base/2)
+		}
+	}
+}
+
+func TestRetryableTransportRetriesOn503(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewRetryableTransport(http.DefaultTransport, 5)}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected eventual 200, got %d", resp.StatusCode)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryAfterPrefersResponseHeader(t *testing.T) {
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"30"}}}
+	if got := retryAfter(resp, 4); got != 30*time.Second {
+		t.Errorf("retryAfter with Retry-After: 30 = %s, want 30s", got)
+	}
+}
+
+func TestRetryAfterFallsBackToBackoff(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	base := time.Duration(1<<2) * 500 * time.Millisecond
+	if got := retryAfter(resp, 2); got < base || got > base+base/2 {
+		t.Errorf("retryAfter with no header = %s, want in [%s, %s]", got, base, base+base/2)
+	}
+}
+
+func TestRetryableTransportDoesNotRetryOn404(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewRetryableTransport(http.DefaultTransport, 5)}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if attempts != 1 {
+		t.Errorf("expected exactly 1 attempt for a non-retryable status, got %d", attempts)
+	}
+}